@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+// Generate renders the Go source declaring a gsql.Statement or gsql.NamedStatement var, wrapper
+// func and Prepare helper for each [Query], gofmt-ed and ready to write out.
+func Generate(pkgName, source string, queries []Query) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, map[string]any{
+		"Package": pkgName,
+		"Source":  source,
+		"Queries": queries,
+	}); err != nil {
+		return nil, fmt.Errorf("render template: %w", err)
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("gofmt output: %w\n%s", err, buf.String())
+	}
+	return formatted, nil
+}
+
+func queryReturns(q Query) string {
+	switch q.Mode {
+	case "many":
+		return "[]" + q.Returns
+	default:
+		return q.Returns
+	}
+}
+
+func queryMethod(q Query) string {
+	switch q.Mode {
+	case "many":
+		return "List"
+	case "iter":
+		return "Iterate"
+	default:
+		return "Get"
+	}
+}
+
+var codeTemplate = template.Must(template.New("gsql-gen").Funcs(template.FuncMap{
+	"returns": queryReturns,
+	"method":  queryMethod,
+}).Parse(`// Code generated by gsql-gen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/reddec/gsql"
+)
+{{range .Queries}}
+{{if .ParamsName}}
+type {{.ParamsName}} {{.ParamsBody}}
+
+// {{.Name}}Query is the SQL behind {{.Name}}, from the "-- name: {{.Name}} :{{.Mode}}" annotation.
+var {{.Name}}Query gsql.NamedStatement[{{.Returns}}, {{.ParamsName}}] = ` + "`{{.SQL}}`" + `
+
+{{if eq .Mode "iter"}}
+// {{.Name}} runs {{.Name}}Query and returns an iterator over the results.
+func {{.Name}}(ctx context.Context, db sqlx.QueryerContext, params {{.ParamsName}}) *gsql.Iterator[{{.Returns}}] {
+	return {{.Name}}Query.{{method .}}(ctx, db, params)
+}
+{{else}}
+// {{.Name}} runs {{.Name}}Query.
+func {{.Name}}(ctx context.Context, db sqlx.QueryerContext, params {{.ParamsName}}) ({{returns .}}, error) {
+	return {{.Name}}Query.{{method .}}(ctx, db, params)
+}
+{{end}}
+// Prepare{{.Name}} prepares {{.Name}}Query for repeated execution.
+func Prepare{{.Name}}(ctx context.Context, db *sqlx.DB) (*sqlx.NamedStmt, error) {
+	return db.PrepareNamedContext(ctx, string({{.Name}}Query))
+}
+{{else}}
+// {{.Name}}Query is the SQL behind {{.Name}}, from the "-- name: {{.Name}} :{{.Mode}}" annotation.
+var {{.Name}}Query gsql.Statement[{{.Returns}}] = ` + "`{{.SQL}}`" + `
+
+{{if eq .Mode "iter"}}
+// {{.Name}} runs {{.Name}}Query and returns an iterator over the results.
+func {{.Name}}(ctx context.Context, db sqlx.QueryerContext, args ...any) *gsql.Iterator[{{.Returns}}] {
+	return {{.Name}}Query.{{method .}}(ctx, db, args...)
+}
+{{else}}
+// {{.Name}} runs {{.Name}}Query.
+func {{.Name}}(ctx context.Context, db sqlx.QueryerContext, args ...any) ({{returns .}}, error) {
+	return {{.Name}}Query.{{method .}}(ctx, db, args...)
+}
+{{end}}
+// Prepare{{.Name}} prepares {{.Name}}Query for repeated execution.
+func Prepare{{.Name}}(ctx context.Context, db *sqlx.DB) (*sqlx.Stmt, error) {
+	return db.PreparexContext(ctx, string({{.Name}}Query))
+}
+{{end}}
+{{end}}
+`))
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	source, err := os.ReadFile("testdata/queries.sql")
+	require.NoError(t, err)
+
+	queries, err := Parse(string(source))
+	require.NoError(t, err)
+
+	code, err := Generate("store", "queries.sql", queries)
+	require.NoError(t, err)
+
+	out := string(code)
+	assert.Contains(t, out, "package store")
+	assert.Contains(t, out, "type GetBookByIDParams struct {")
+	assert.Contains(t, out, "var GetBookByIDQuery gsql.NamedStatement[Book, GetBookByIDParams]")
+	assert.Contains(t, out, "func GetBookByID(ctx context.Context, db sqlx.QueryerContext, params GetBookByIDParams) (Book, error)")
+	assert.Contains(t, out, "var ListBooksByAuthorQuery gsql.NamedStatement[Book, ListBooksByAuthorParams]")
+	assert.Contains(t, out, "func ListBooksByAuthor(ctx context.Context, db sqlx.QueryerContext, params ListBooksByAuthorParams) ([]Book, error)")
+	assert.Contains(t, out, "var IterateBooksQuery gsql.Statement[Book]")
+	assert.Contains(t, out, "func IterateBooks(ctx context.Context, db sqlx.QueryerContext, args ...any) *gsql.Iterator[Book]")
+}
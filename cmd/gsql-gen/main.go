@@ -0,0 +1,67 @@
+// Command gsql-gen parses a SQL file annotated with sqlc-style `-- name: Query :one` /
+// `:many` / `:iter` directives and emits Go code declaring a [gsql.NamedStatement] or
+// [gsql.Statement] plus a thin wrapper func for each one, ready to call into from application
+// code with zero extra runtime. Typical use is a `go:generate` directive next to the SQL file:
+//
+//	//go:generate go run github.com/reddec/gsql/cmd/gsql-gen -in queries.sql -out queries_gen.go
+//
+// See the doc comment of [Query] in cmd/gsql-gen/parser.go for the annotation format.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	in := flag.String("in", "", "input annotated SQL file (required)")
+	out := flag.String("out", "", "output Go file (default: <in> with .go extension)")
+	pkgName := flag.String("package", "", "output package name (default: name of the output directory)")
+	flag.Parse()
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "gsql-gen: -in is required")
+		os.Exit(2)
+	}
+
+	if err := run(*in, *out, *pkgName); err != nil {
+		fmt.Fprintln(os.Stderr, "gsql-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkgName string) error {
+	source, err := os.ReadFile(in)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", in, err)
+	}
+
+	queries, err := Parse(string(source))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", in, err)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("%s: no `-- name:` annotated queries found", in)
+	}
+
+	if out == "" {
+		out = strings.TrimSuffix(in, filepath.Ext(in)) + ".go"
+	}
+	if pkgName == "" {
+		absOut, err := filepath.Abs(out)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", out, err)
+		}
+		pkgName = filepath.Base(filepath.Dir(absOut))
+	}
+
+	code, err := Generate(pkgName, filepath.Base(in), queries)
+	if err != nil {
+		return fmt.Errorf("generate %s: %w", out, err)
+	}
+
+	return os.WriteFile(out, code, 0o644)
+}
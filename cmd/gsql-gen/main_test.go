@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunDefaultPackageNameNoDir exercises the invocation shown in this package's doc comment:
+// `-out queries_gen.go` with no directory component and no `-package` flag. Resolving `out`
+// relative to the current directory must yield the working directory's base name, not ".".
+func TestRunDefaultPackageNameNoDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "storepkg")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	require.NoError(t, os.WriteFile("queries.sql", []byte(
+		"-- name: GetBook :one\n-- returns: Book\nSELECT * FROM book WHERE id = ?;\n",
+	), 0o644))
+
+	require.NoError(t, run("queries.sql", "queries_gen.go", ""))
+
+	code, err := os.ReadFile("queries_gen.go")
+	require.NoError(t, err)
+
+	wantPkg := filepath.Base(dir)
+	require.Contains(t, string(code), "package "+wantPkg)
+	require.NotContains(t, string(code), "package .\n")
+}
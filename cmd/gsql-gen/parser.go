@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Query is one annotated block parsed out of a SQL file: a `-- name: Name :mode` header,
+// optional `-- param:` / `-- returns:` directives, and the SQL statement itself.
+//
+// Annotation format:
+//
+//	-- name: GetBookByID :one
+//	-- param: GetBookByIDParams struct {
+//	--     ID int64 `db:"id"`
+//	-- }
+//	-- returns: Book
+//	SELECT * FROM book WHERE id = :id;
+//
+// `:one` returns a single row, `:many` a slice, `:iter` a [gsql.Iterator]. `-- param:` is
+// optional; without it, the generated wrapper takes positional `args ...any` instead of a
+// named Params struct. `-- returns:` names an existing Go type already in scope in the target
+// package (e.g. Book, defined by hand elsewhere).
+type Query struct {
+	Name       string // Go identifier, from `-- name:`
+	Mode       string // one, many or iter
+	ParamsName string // Go type name, from `-- param:`; empty when the query takes positional args
+	ParamsBody string // verbatim `struct { ... }` body, from `-- param:`
+	Returns    string // Go type name, from `-- returns:`
+	SQL        string // the statement itself, verbatim
+}
+
+var (
+	nameDirective    = regexp.MustCompile(`^--\s*name:\s*(\w+)\s+:(one|many|iter)\s*$`)
+	paramStart       = regexp.MustCompile(`^--\s*param:\s*(\w+)\s+struct\s*\{\s*$`)
+	returnsDirective = regexp.MustCompile(`^--\s*returns:\s*(\S+)\s*$`)
+)
+
+// Parse splits an annotated SQL file into [Query] blocks. Each block starts with a
+// `-- name: Name :mode` line and runs until the next one (or EOF); plain `--` comments that
+// aren't recognized directives are skipped, and text before the first `-- name:` is ignored.
+func Parse(source string) ([]Query, error) {
+	var queries []Query
+	var cur *Query
+	var body []string
+	var paramBody []string
+	collectingParams := false
+
+	flush := func() error {
+		if cur == nil {
+			return nil
+		}
+		if collectingParams {
+			return fmt.Errorf("query %q: `-- param:` struct is never closed with `-- }`", cur.Name)
+		}
+		cur.SQL = strings.TrimSpace(strings.Join(body, "\n"))
+		if cur.SQL == "" {
+			return fmt.Errorf("query %q: empty SQL body", cur.Name)
+		}
+		if cur.Returns == "" {
+			return fmt.Errorf("query %q: missing `-- returns:` directive", cur.Name)
+		}
+		queries = append(queries, *cur)
+		cur, body = nil, nil
+		return nil
+	}
+
+	for _, raw := range strings.Split(source, "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		if collectingParams {
+			if trimmed == "--" || trimmed == "--}" || trimmed == "-- }" {
+				cur.ParamsBody = "struct {\n" + strings.Join(paramBody, "\n") + "\n}"
+				collectingParams, paramBody = false, nil
+				continue
+			}
+			paramBody = append(paramBody, strings.TrimPrefix(strings.TrimPrefix(trimmed, "--"), " "))
+			continue
+		}
+
+		switch {
+		case nameDirective.MatchString(trimmed):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			m := nameDirective.FindStringSubmatch(trimmed)
+			cur = &Query{Name: m[1], Mode: m[2]}
+		case cur == nil:
+			// preamble before the first `-- name:`, ignored
+		case paramStart.MatchString(trimmed):
+			cur.ParamsName = paramStart.FindStringSubmatch(trimmed)[1]
+			collectingParams = true
+		case returnsDirective.MatchString(trimmed):
+			cur.Returns = returnsDirective.FindStringSubmatch(trimmed)[1]
+		case strings.HasPrefix(trimmed, "--"):
+			// plain comment, ignored
+		default:
+			body = append(body, line)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	source, err := os.ReadFile("testdata/queries.sql")
+	require.NoError(t, err)
+
+	queries, err := Parse(string(source))
+	require.NoError(t, err)
+	require.Len(t, queries, 3)
+
+	get := queries[0]
+	assert.Equal(t, "GetBookByID", get.Name)
+	assert.Equal(t, "one", get.Mode)
+	assert.Equal(t, "GetBookByIDParams", get.ParamsName)
+	assert.Equal(t, "struct {\n    ID int64 `db:\"id\"`\n}", get.ParamsBody)
+	assert.Equal(t, "Book", get.Returns)
+	assert.Equal(t, "SELECT * FROM book WHERE id = :id;", get.SQL)
+
+	list := queries[1]
+	assert.Equal(t, "ListBooksByAuthor", list.Name)
+	assert.Equal(t, "many", list.Mode)
+	assert.Equal(t, "ListBooksByAuthorParams", list.ParamsName)
+
+	iter := queries[2]
+	assert.Equal(t, "IterateBooks", iter.Name)
+	assert.Equal(t, "iter", iter.Mode)
+	assert.Empty(t, iter.ParamsName)
+}
+
+func TestParseUnclosedParamStruct(t *testing.T) {
+	_, err := Parse("-- name: Broken :one\n-- param: P struct {\n-- returns: Book\nSELECT 1;")
+	require.Error(t, err)
+}
+
+func TestParseMissingReturns(t *testing.T) {
+	_, err := Parse("-- name: Broken :one\nSELECT 1;")
+	require.Error(t, err)
+}
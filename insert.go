@@ -0,0 +1,215 @@
+package gsql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// insertMapper mirrors the default struct mapper sqlx uses internally, so that [Insert]
+// discovers columns from the same `db:"..."` tags already relied on by [Get] and [List].
+var insertMapper = reflectx.NewMapperFunc("db", strings.ToLower)
+
+// placeholderLimit caps how many bound parameters a single statement may carry for a given
+// driver. Rows are chunked by [Insert] so a bulk statement never exceeds it.
+var placeholderLimit = map[string]int{
+	"sqlite3":  999,
+	"sqlite":   999,
+	"postgres": 65535,
+	"pgx":      65535,
+}
+
+const defaultPlaceholderLimit = 65535
+
+// InsertOption customizes [Insert], e.g. turning it into an upsert or requesting RETURNING columns.
+type InsertOption func(*insertConfig)
+
+type insertConfig struct {
+	conflictTarget string
+	conflictAction string
+	returning      []string
+}
+
+// WithConflict turns [Insert] into an upsert by appending `ON CONFLICT (target) action`,
+// for example WithConflict("id", "DO UPDATE SET title = excluded.title").
+func WithConflict(target, action string) InsertOption {
+	return func(cfg *insertConfig) {
+		cfg.conflictTarget = target
+		cfg.conflictAction = action
+	}
+}
+
+// WithReturning appends a RETURNING clause with the given columns and causes [Insert] to scan
+// and return the inserted/updated rows instead of echoing back the input.
+func WithReturning(columns ...string) InsertOption {
+	return func(cfg *insertConfig) {
+		cfg.returning = columns
+	}
+}
+
+// Insert bulk-inserts rows into table as a single multi-row INSERT statement, discovering
+// columns from the same `db:"..."` struct tags used for scanning by [Get] and [List]. Rows are
+// automatically chunked so the number of bound placeholders never exceeds the driver's limit
+// (999 for SQLite, 65535 for Postgres and others). Use [WithConflict] to upsert and
+// [WithReturning] to get back generated or updated columns; without it, rows is returned
+// unchanged on success.
+//
+// T should not carry an autoincrement primary key unless it is always explicitly set: a zero
+// value is inserted as-is and will collide across rows of the same chunk. Declare a dedicated
+// row type without that field, or use [WithReturning] to read the generated value back.
+func Insert[T any](ctx context.Context, db sqlx.ExtContext, table string, rows []T, opts ...InsertOption) ([]T, error) {
+	if len(rows) == 0 {
+		return rows, nil
+	}
+
+	var cfg insertConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	columns, err := insertColumns[T]()
+	if err != nil {
+		return nil, fmt.Errorf("discover columns: %w", err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%T has no db-tagged columns to insert", *new(T))
+	}
+
+	limit, ok := placeholderLimit[db.DriverName()]
+	if !ok {
+		limit = defaultPlaceholderLimit
+	}
+	chunkSize := limit / len(columns)
+	if chunkSize == 0 {
+		return nil, fmt.Errorf("too many columns (%d) for placeholder limit (%d)", len(columns), limit)
+	}
+
+	var returned []T
+	for start := 0; start < len(rows); start += chunkSize {
+		end := min(start+chunkSize, len(rows))
+
+		query, args := buildInsert(table, columns, rows[start:end], cfg)
+		query = db.Rebind(query)
+
+		if len(cfg.returning) == 0 {
+			if _, err := db.ExecContext(ctx, query, args...); err != nil {
+				return nil, fmt.Errorf("insert rows %d-%d: %w", start, end, err)
+			}
+			continue
+		}
+
+		scanned, err := List[T](ctx, db, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("insert rows %d-%d: %w", start, end, err)
+		}
+		returned = append(returned, scanned...)
+	}
+
+	if len(cfg.returning) == 0 {
+		return rows, nil
+	}
+	return returned, nil
+}
+
+// insertColumn pairs a discovered column name with the struct traversal used to read it,
+// so a value can be extracted without re-resolving the name through [insertMapper] per row.
+type insertColumn struct {
+	name  string
+	index []int
+}
+
+// buildInsert renders the INSERT statement (with `?` placeholders, rebound by the caller)
+// and the flat argument list for a chunk of rows.
+func buildInsert[T any](table string, columns []insertColumn, rows []T, cfg insertConfig) (string, []any) {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(table)
+	sb.WriteString(" (")
+	sb.WriteString(strings.Join(names, ", "))
+	sb.WriteString(") VALUES ")
+
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	args := make([]any, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(placeholder)
+		args = append(args, insertValues(row, columns)...)
+	}
+
+	if cfg.conflictTarget != "" {
+		sb.WriteString(" ON CONFLICT (")
+		sb.WriteString(cfg.conflictTarget)
+		sb.WriteString(") ")
+		sb.WriteString(cfg.conflictAction)
+	}
+
+	if len(cfg.returning) > 0 {
+		sb.WriteString(" RETURNING ")
+		sb.WriteString(strings.Join(cfg.returning, ", "))
+	}
+
+	return sb.String(), args
+}
+
+// insertColumns lists the columns of T following the `db:"..."` tags, including those promoted
+// from anonymous embedded structs (the same fields [Get] and [List] scan into via
+// [insertMapper]). Columns come in the mapper's field index order: breadth-first, so a struct's
+// direct fields are listed before those promoted from its embedded structs.
+func insertColumns[T any]() ([]insertColumn, error) {
+	t := reflect.TypeOf(*new(T))
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+
+	fields := insertMapper.TypeMap(t).Index
+	columns := make([]insertColumn, 0, len(fields))
+	for _, fi := range fields {
+		if fi.Name == "-" || fi.Embedded || !promotedThroughEmbedding(fi) {
+			continue
+		}
+		columns = append(columns, insertColumn{name: fi.Name, index: fi.Index})
+	}
+	return columns, nil
+}
+
+// promotedThroughEmbedding reports whether fi is a direct field of the row type, or was
+// promoted to the top level solely by walking through anonymous embedded structs. It excludes
+// fields nested inside a named struct-typed column, such as the Data field the mapper finds
+// inside a [JSON] column: Insert treats the column itself (e.g. JSON's driver.Valuer) as the
+// value, not its internals.
+func promotedThroughEmbedding(fi *reflectx.FieldInfo) bool {
+	for p := fi.Parent; p != nil && p.Parent != nil; p = p.Parent {
+		if !p.Embedded {
+			return false
+		}
+	}
+	return true
+}
+
+// insertValues extracts the value of each column from row, in the same order as columns. row
+// arrives as a non-addressable copy, so fields are read with [reflectx.FieldByIndexesReadOnly]
+// rather than [reflectx.Mapper.FieldByName]: the latter allocates through nil pointers to make
+// scan destinations addressable, which panics on a non-addressable value with a nil pointer field.
+func insertValues(row any, columns []insertColumn) []any {
+	v := reflect.ValueOf(row)
+	values := make([]any, len(columns))
+	for i, col := range columns {
+		values[i] = reflectx.FieldByIndexesReadOnly(v, col.index).Interface()
+	}
+	return values
+}
@@ -0,0 +1,50 @@
+//go:build go1.23
+
+package gsql
+
+import (
+	"context"
+	"iter"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// All returns an [iter.Seq2] that yields each row scanned into T alongside a possible error,
+// for use with Go 1.23 range-over-func:
+//
+//	for book, err := range gsql.Iterate[Book](ctx, db, query).All() {
+//		if err != nil { ... }
+//	}
+//
+// The underlying cursor is closed once the loop terminates, whether by exhausting all rows, the
+// loop body breaking early, or an error being yielded. The initial [sqlx.QueryerContext] error,
+// if any, is surfaced as the first and only yielded pair.
+func (it *Iterator[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer it.Close()
+
+		if it.err != nil {
+			var zero T
+			yield(zero, it.err)
+			return
+		}
+
+		for it.Next() {
+			value, err := it.Get()
+			if !yield(value, err) || err != nil {
+				return
+			}
+		}
+		if err := it.rows.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// Range is a convenience wrapper around [Iterate] for use with Go 1.23 range-over-func:
+//
+//	for book, err := range gsql.Range[Book](ctx, db, query, args...) { ... }
+func Range[T any](ctx context.Context, db sqlx.QueryerContext, query string, args ...any) iter.Seq2[T, error] {
+	return Iterate[T](ctx, db, query, args...).All()
+}
@@ -0,0 +1,169 @@
+package gsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Middleware observes queries executed through a [WrapDB]-wrapped database handle. Before runs
+// ahead of the query and may return a derived context (e.g. carrying a span) that is passed
+// through to After once the query completes.
+type Middleware interface {
+	Before(ctx context.Context, query string, args []any) context.Context
+	After(ctx context.Context, query string, err error, duration time.Duration)
+}
+
+// WrapDB returns an adapter around db that runs mws around every query, in order: all
+// middlewares' Before run before the query, then After runs in reverse order once it completes.
+// The adapter satisfies [sqlx.ExtContext], so it can be passed anywhere a plain db could,
+// including to [Insert] - but ExecContext, DriverName, and BindNamed only work when db itself
+// implements the corresponding behaviour (as *sqlx.DB and *sqlx.Tx do); otherwise ExecContext
+// and BindNamed return an error and DriverName returns "".
+func WrapDB(db sqlx.QueryerContext, mws ...Middleware) sqlx.ExtContext {
+	return &instrumentedDB{db: db, mws: mws}
+}
+
+type instrumentedDB struct {
+	db  sqlx.QueryerContext
+	mws []Middleware
+}
+
+func (w *instrumentedDB) before(ctx context.Context, query string, args []any) (context.Context, func(error)) {
+	if len(w.mws) == 0 {
+		return ctx, func(error) {}
+	}
+	start := time.Now()
+	for _, mw := range w.mws {
+		ctx = mw.Before(ctx, query, args)
+	}
+	return ctx, func(err error) {
+		duration := time.Since(start)
+		for i := len(w.mws) - 1; i >= 0; i-- {
+			w.mws[i].After(ctx, query, err, duration)
+		}
+	}
+}
+
+func (w *instrumentedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, done := w.before(ctx, query, args)
+	rows, err := w.db.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (w *instrumentedDB) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	ctx, done := w.before(ctx, query, args)
+	rows, err := w.db.QueryxContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (w *instrumentedDB) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	ctx, done := w.before(ctx, query, args)
+	row := w.db.QueryRowxContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
+
+// ExecContext instruments the query the same way as QueryContext, when the wrapped db supports
+// [sqlx.ExecerContext]. It returns an error otherwise.
+func (w *instrumentedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	execer, ok := w.db.(sqlx.ExecerContext)
+	if !ok {
+		return nil, fmt.Errorf("gsql: %T does not support ExecContext", w.db)
+	}
+	ctx, done := w.before(ctx, query, args)
+	res, err := execer.ExecContext(ctx, query, args...)
+	done(err)
+	return res, err
+}
+
+// binderLike mirrors sqlx's unexported binder interface, satisfied by *sqlx.DB and *sqlx.Tx,
+// so the wrapped db's binding behaviour (used to build [sqlx.ExtContext]) can be detected and
+// forwarded without depending on sqlx internals.
+type binderLike interface {
+	DriverName() string
+	Rebind(string) string
+	BindNamed(string, any) (string, []any, error)
+}
+
+// DriverName forwards to the wrapped db when it implements the binder behaviour of
+// [sqlx.ExtContext], so the adapter keeps working as one for callers like [Insert].
+func (w *instrumentedDB) DriverName() string {
+	binder, ok := w.db.(binderLike)
+	if !ok {
+		return ""
+	}
+	return binder.DriverName()
+}
+
+// Rebind forwards to the wrapped db when it implements the binder behaviour of
+// [sqlx.ExtContext], or returns query unchanged otherwise.
+func (w *instrumentedDB) Rebind(query string) string {
+	binder, ok := w.db.(binderLike)
+	if !ok {
+		return query
+	}
+	return binder.Rebind(query)
+}
+
+// BindNamed forwards to the wrapped db when it implements the binder behaviour of
+// [sqlx.ExtContext].
+func (w *instrumentedDB) BindNamed(query string, arg any) (string, []any, error) {
+	binder, ok := w.db.(binderLike)
+	if !ok {
+		return "", nil, fmt.Errorf("gsql: %T does not support BindNamed", w.db)
+	}
+	return binder.BindNamed(query, arg)
+}
+
+// SlowQueryLogger is a [Middleware] that logs, via logger, any query whose execution exceeds
+// threshold. A nil logger falls back to [slog.Default].
+func SlowQueryLogger(threshold time.Duration, logger *slog.Logger) Middleware {
+	return &slowQueryLogger{threshold: threshold, logger: logger}
+}
+
+type slowQueryLogger struct {
+	threshold time.Duration
+	logger    *slog.Logger
+}
+
+func (m *slowQueryLogger) Before(ctx context.Context, _ string, _ []any) context.Context {
+	return ctx
+}
+
+func (m *slowQueryLogger) After(ctx context.Context, query string, err error, duration time.Duration) {
+	if duration < m.threshold {
+		return
+	}
+	logger := m.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.WarnContext(ctx, "slow query", "query", query, "duration", duration, "error", err)
+}
+
+var statementNameDirective = regexp.MustCompile(`(?m)^--\s*name:\s*(\w+)`)
+
+// StatementName returns the name a query should be reported under by a [Middleware]: the value
+// of the first line in the query starting with a `-- name: X` comment (the same annotation
+// [cmd/gsql-gen] reads), or the first 40
+// characters of the query otherwise. Built-in middlewares in gsql/otelgsql and gsql/promgsql use
+// it to label spans and metrics.
+func StatementName(query string) string {
+	if m := statementNameDirective.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	q := strings.TrimSpace(query)
+	if len(q) > 40 {
+		q = q[:40]
+	}
+	return q
+}
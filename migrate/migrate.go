@@ -0,0 +1,279 @@
+// Package migrate applies versioned SQL schema migrations against a database, tracking
+// progress in a schema_migrations table so partially-applied migrations can be detected and
+// reported.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrDirty is returned when the schema was left in a partially-applied state by a previous,
+// failed migration attempt. Inspect [Migrator.Version] and fix the schema (or the migration)
+// before retrying.
+var ErrDirty = errors.New("migrate: database is dirty")
+
+// ErrNoMigration is returned by [Migrator.Goto] when the requested version has no matching migration.
+var ErrNoMigration = errors.New("migrate: no such migration")
+
+const schemaMigrationsDDL = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    dirty BOOLEAN NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+// Migration is a single versioned pair of SQL scripts loaded from a `<version>_<name>.up.sql` /
+// `.down.sql` file pair.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies versioned [Migration] against a database, tracking progress in the
+// schema_migrations table.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration // sorted ascending by Version
+}
+
+// New loads `<version>_<name>.up.sql` / `.down.sql` pairs from fsys (typically an [embed.FS])
+// and returns a [Migrator] that applies them against db. db.DriverName() picks the bind
+// variable style (`?` vs `$1`) used to maintain the internal schema_migrations table; the same
+// SQL and the same per-migration transaction are used on every backend. Note that on MySQL, DDL statements implicitly
+// commit a transaction, so a crash mid-migration there is reported as dirty but cannot be
+// wrapped atomically with the DDL itself.
+func New(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse version in %q: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read %q: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Version returns the highest applied migration version and whether it was left dirty by a
+// failed attempt. It returns version zero, dirty false, and a nil error when nothing has been
+// applied yet.
+func (m *Migrator) Version(ctx context.Context) (version int64, dirty bool, err error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+
+	row := m.db.QueryRowxContext(ctx, "SELECT version, dirty FROM schema_migrations ORDER BY version DESC LIMIT 1")
+	if err := row.Scan(&version, &dirty); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("read current version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, schemaMigrationsDDL)
+	return err
+}
+
+// Up applies all pending migrations in ascending order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.Goto(ctx, m.latestVersion())
+}
+
+// Down rolls back up to steps applied migrations in descending order. Requesting more steps
+// than are applied stops cleanly at version zero. steps must be non-negative.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps < 0 {
+		return fmt.Errorf("migrate: steps must be >= 0, got %d", steps)
+	}
+
+	current, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: at version %d", ErrDirty, current)
+	}
+
+	pending := m.between(0, current, false)
+	if steps < len(pending) {
+		pending = pending[:steps]
+	}
+	for _, migration := range pending {
+		if err := m.apply(ctx, migration, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Goto migrates up or down until the schema is exactly at version, applying every migration in
+// between. A version of zero rolls back everything.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	current, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: at version %d", ErrDirty, current)
+	}
+	if version != 0 && !m.exists(version) {
+		return fmt.Errorf("%w: %d", ErrNoMigration, version)
+	}
+
+	switch {
+	case version > current:
+		for _, migration := range m.between(current, version, true) {
+			if err := m.apply(ctx, migration, true); err != nil {
+				return err
+			}
+		}
+	case version < current:
+		for _, migration := range m.between(version, current, false) {
+			if err := m.apply(ctx, migration, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) latestVersion() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) exists(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// between returns migrations with low < Version <= high, ordered ascending or descending.
+func (m *Migrator) between(low, high int64, ascending bool) []Migration {
+	var result []Migration
+	for _, migration := range m.migrations {
+		if migration.Version > low && migration.Version <= high {
+			result = append(result, migration)
+		}
+	}
+	if !ascending {
+		sort.Slice(result, func(i, j int) bool { return result[i].Version > result[j].Version })
+	}
+	return result
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration, up bool) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	// Committed on its own, ahead of the migration transaction below, so that on a backend
+	// with transactional DDL (sqlite, postgres) a failing migration rolling back its
+	// transaction does not also roll back the dirty marker - otherwise Version would report
+	// the prior, clean version instead of surfacing ErrDirty.
+	if err := m.markDirty(ctx, migration, up); err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	script := migration.Up
+	if !up {
+		script = migration.Down
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("apply migration %d (%s): %w", migration.Version, migration.Name, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, tx.Rebind("UPDATE schema_migrations SET dirty = ? WHERE version = ?"), false, migration.Version)
+	} else {
+		_, err = tx.ExecContext(ctx, tx.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("finalize migration %d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// markDirty records migration's dirty flag (inserting the row for an up migration, or flipping
+// it for a down migration) in its own implicit transaction, committed before the migration
+// script runs.
+func (m *Migrator) markDirty(ctx context.Context, migration Migration, up bool) error {
+	var err error
+	if up {
+		_, err = m.db.ExecContext(ctx, m.db.Rebind("INSERT INTO schema_migrations (version, dirty, applied_at) VALUES (?, ?, ?)"), migration.Version, true, time.Now().UTC())
+	} else {
+		_, err = m.db.ExecContext(ctx, m.db.Rebind("UPDATE schema_migrations SET dirty = ? WHERE version = ?"), true, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("mark migration %d dirty: %w", migration.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package migrate_test
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/reddec/gsql/migrate"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed testdata
+var testdataFS embed.FS
+
+//go:embed testdata_broken
+var testdataBrokenFS embed.FS
+
+func testdata(t *testing.T) fs.FS {
+	t.Helper()
+	fsys, err := fs.Sub(testdataFS, "testdata")
+	require.NoError(t, err)
+	return fsys
+}
+
+func testdataBroken(t *testing.T) fs.FS {
+	t.Helper()
+	fsys, err := fs.Sub(testdataBrokenFS, "testdata_broken")
+	require.NoError(t, err)
+	return fsys
+}
+
+func TestMigratorUpDownGoto(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := migrate.New(db, testdata(t))
+	require.NoError(t, err)
+
+	version, dirty, err := m.Version(ctx)
+	require.NoError(t, err)
+	require.Zero(t, version)
+	require.False(t, dirty)
+
+	require.NoError(t, m.Up(ctx))
+
+	version, dirty, err = m.Version(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, version)
+	require.False(t, dirty)
+
+	_, err = db.ExecContext(ctx, "INSERT INTO book (id, title, author) VALUES (1, 'Dune', 'Frank Herbert')")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Down(ctx, 1))
+
+	version, _, err = m.Version(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, version)
+
+	require.NoError(t, m.Goto(ctx, 0))
+
+	version, _, err = m.Version(ctx)
+	require.NoError(t, err)
+	require.Zero(t, version)
+}
+
+func TestMigratorGotoUnknownVersion(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := migrate.New(db, testdata(t))
+	require.NoError(t, err)
+
+	err = m.Goto(ctx, 999)
+	require.ErrorIs(t, err, migrate.ErrNoMigration)
+}
+
+func TestMigratorFailedMigrationLeavesDirty(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := migrate.New(db, testdataBroken(t))
+	require.NoError(t, err)
+
+	err = m.Up(ctx)
+	require.Error(t, err)
+
+	version, dirty, err := m.Version(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, version)
+	require.True(t, dirty, "the failed migration's transaction rolling back must not roll back its dirty marker")
+}
+
+func TestMigratorDownNegativeSteps(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := migrate.New(db, testdata(t))
+	require.NoError(t, err)
+	require.NoError(t, m.Up(ctx))
+
+	err = m.Down(ctx, -1)
+	require.Error(t, err)
+}
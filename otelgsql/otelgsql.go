@@ -0,0 +1,49 @@
+// Package otelgsql provides an OpenTelemetry tracing [gsql.Middleware] for gsql. It is kept in
+// its own module-level package so the core gsql package stays free of the otel dependency for
+// callers who don't need it.
+package otelgsql
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/reddec/gsql"
+)
+
+// Middleware opens a span per query using tracer, named after the query's
+// [gsql.StatementName] (a leading `-- name: X` comment, or the first 40 characters of the
+// query). It records the db.system and db.statement attributes and marks the span as failed
+// when the query returns an error.
+func Middleware(tracer trace.Tracer) gsql.Middleware {
+	return &middleware{tracer: tracer}
+}
+
+type middleware struct {
+	tracer trace.Tracer
+}
+
+type spanKey struct{}
+
+func (m *middleware) Before(ctx context.Context, query string, _ []any) context.Context {
+	ctx, span := m.tracer.Start(ctx, gsql.StatementName(query), trace.WithAttributes(
+		attribute.String("db.system", "sql"),
+		attribute.String("db.statement", query),
+	))
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func (m *middleware) After(ctx context.Context, _ string, err error, _ time.Duration) {
+	span, ok := ctx.Value(spanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
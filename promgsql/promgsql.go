@@ -0,0 +1,33 @@
+// Package promgsql provides a Prometheus [gsql.Middleware] for gsql. It is kept in its own
+// module-level package so the core gsql package stays free of the prometheus dependency for
+// callers who don't need it.
+package promgsql
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/reddec/gsql"
+)
+
+// Middleware observes each query's duration, in seconds, in histogram - labeled by the query's
+// [gsql.StatementName] (a leading `-- name: X` comment, or the first 40 characters of the
+// query). histogram must already be registered by the caller; it needs exactly one label,
+// conventionally named "query".
+func Middleware(histogram *prometheus.HistogramVec) gsql.Middleware {
+	return &middleware{histogram: histogram}
+}
+
+type middleware struct {
+	histogram *prometheus.HistogramVec
+}
+
+func (m *middleware) Before(ctx context.Context, _ string, _ []any) context.Context {
+	return ctx
+}
+
+func (m *middleware) After(_ context.Context, query string, _ error, duration time.Duration) {
+	m.histogram.WithLabelValues(gsql.StatementName(query)).Observe(duration.Seconds())
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -47,39 +48,128 @@ func LazyList[T any](db sqlx.QueryerContext, query string, args ...any) func(ctx
 
 // Cache stores data and factory to get data.
 type Cache[T any] struct {
-	lock    sync.RWMutex
-	data    T
-	valid   bool
-	factory func(ctx context.Context) (T, error)
+	factory     func(ctx context.Context) (T, error)
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	lock      sync.RWMutex
+	data      T
+	lastErr   error
+	valid     bool
+	fetchedAt time.Time
+
+	inflightLock sync.Mutex
+	inflight     chan struct{}
+}
+
+// CacheOption customizes a [Cache] created by [NewCacheWithOptions].
+type CacheOption func(*cacheOptions)
+
+type cacheOptions struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// WithTTL expires a cached value once it is older than d, counting from when factory last
+// succeeded in [Cache.Get] or [Cache.Refresh]; a Get served from the cache does not restart it.
+// Without it, a value stays valid until [Cache.Invalidate].
+func WithTTL(d time.Duration) CacheOption {
+	return func(o *cacheOptions) { o.ttl = d }
+}
+
+// WithNegativeTTL caches a failing factory call's error for d, so a struggling dependency isn't
+// hit again on every [Cache.Get] until it expires. Without it, a failed call is never cached.
+func WithNegativeTTL(d time.Duration) CacheOption {
+	return func(o *cacheOptions) { o.negativeTTL = d }
 }
 
 // NewCache creates new concurrent-safe cache for internal data.
 func NewCache[T any](factory func(ctx context.Context) (T, error)) *Cache[T] {
-	return &Cache[T]{factory: factory}
+	return NewCacheWithOptions[T](factory)
+}
+
+// NewCacheWithOptions is like [NewCache] but accepts [CacheOption], such as [WithTTL] and [WithNegativeTTL].
+func NewCacheWithOptions[T any](factory func(ctx context.Context) (T, error), opts ...CacheOption) *Cache[T] {
+	var o cacheOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Cache[T]{factory: factory, ttl: o.ttl, negativeTTL: o.negativeTTL}
+}
+
+// expired reports whether the cached entry must be refreshed: never cached, invalidated, or
+// past its TTL (the negative TTL is used instead when the cached outcome is an error). A
+// negative TTL of zero means a cached error is never reused - it always counts as expired, so
+// every [Cache.Get] retries factory - per [WithNegativeTTL]'s doc comment. Must be called with
+// lock held for read or write.
+func (ct *Cache[T]) expired() bool {
+	if !ct.valid {
+		return true
+	}
+	if ct.lastErr != nil {
+		if ct.negativeTTL <= 0 {
+			return true
+		}
+		return time.Since(ct.fetchedAt) >= ct.negativeTTL
+	}
+	if ct.ttl <= 0 {
+		return false
+	}
+	return time.Since(ct.fetchedAt) >= ct.ttl
 }
 
 // Get content from cache or from storage. Once data fetched, it will be stored internally.
+// Concurrent calls that observe an invalid or expired cache collapse into a single factory
+// invocation instead of each re-fetching; every caller receives the same result.
 func (ct *Cache[T]) Get(ctx context.Context) (T, error) {
 	ct.lock.RLock()
-	valid := ct.valid
-	data := ct.data
+	stale := ct.expired()
+	data, lastErr := ct.data, ct.lastErr
 	ct.lock.RUnlock()
-	if valid {
-		return data, nil
+	if !stale {
+		return data, lastErr
 	}
-	ct.lock.Lock()
-	defer ct.lock.Unlock()
-	if ct.valid {
-		return ct.data, nil
+
+	return ct.fetch(ctx)
+}
+
+// fetch runs factory at most once per concurrent wave of stale [Cache.Get] calls: the first
+// caller to arrive runs factory and closes the in-flight channel once done, every other caller
+// blocks on that channel and reads out the same outcome.
+func (ct *Cache[T]) fetch(ctx context.Context) (T, error) {
+	ct.inflightLock.Lock()
+	if ch := ct.inflight; ch != nil {
+		ct.inflightLock.Unlock()
+		<-ch
+		ct.lock.RLock()
+		defer ct.lock.RUnlock()
+		return ct.data, ct.lastErr
 	}
+	ch := make(chan struct{})
+	ct.inflight = ch
+	ct.inflightLock.Unlock()
 
 	value, err := ct.factory(ctx)
-	if err != nil {
-		return ct.data, err
+
+	ct.lock.Lock()
+	switch {
+	case err == nil:
+		ct.data, ct.lastErr, ct.valid, ct.fetchedAt = value, nil, true, time.Now()
+	case ct.negativeTTL > 0:
+		var zero T
+		ct.data, ct.lastErr, ct.valid, ct.fetchedAt = zero, err, true, time.Now()
+	default:
+		// keep the last known good value cached (if any), but surface the fresh error to every caller.
+		value, ct.lastErr = ct.data, err
 	}
-	ct.data = value
-	ct.valid = true
-	return ct.data, nil
+	ct.lock.Unlock()
+
+	ct.inflightLock.Lock()
+	ct.inflight = nil
+	ct.inflightLock.Unlock()
+	close(ch)
+
+	return value, err
 }
 
 // Refresh cache regardless of validity.
@@ -88,10 +178,13 @@ func (ct *Cache[T]) Refresh(ctx context.Context) error {
 	defer ct.lock.Unlock()
 	value, err := ct.factory(ctx)
 	if err != nil {
+		if ct.negativeTTL > 0 {
+			var zero T
+			ct.data, ct.lastErr, ct.valid, ct.fetchedAt = zero, err, true, time.Now()
+		}
 		return err
 	}
-	ct.data = value
-	ct.valid = true
+	ct.data, ct.lastErr, ct.valid, ct.fetchedAt = value, nil, true, time.Now()
 	return nil
 }
 
@@ -100,6 +193,19 @@ func (ct *Cache[T]) Invalidate() {
 	ct.lock.Lock()
 	defer ct.lock.Unlock()
 	ct.valid = false
+	ct.lastErr = nil
+}
+
+// Peek returns the currently cached value without triggering a fetch. ok is false when nothing
+// has been cached yet, the cache was invalidated or has expired, or the last fetch failed.
+func (ct *Cache[T]) Peek() (T, bool) {
+	ct.lock.RLock()
+	defer ct.lock.RUnlock()
+	if ct.lastErr != nil || ct.expired() {
+		var zero T
+		return zero, false
+	}
+	return ct.data, true
 }
 
 // CachedGet is alias of [NewCache]([LazyGet]) and provides cached information from database.
@@ -145,8 +251,12 @@ func (it *Iterator[T]) Err() error {
 	return it.rows.Err()
 }
 
-// Close database cursor and allocated resources.
+// Close database cursor and allocated resources. Safe to call when the initial query failed
+// and no cursor was ever opened.
 func (it *Iterator[T]) Close() error {
+	if it.rows == nil {
+		return nil
+	}
 	return it.rows.Close()
 }
 
@@ -0,0 +1,90 @@
+//go:build go1.23
+
+package test_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/reddec/gsql"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestIteratorAll(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	var got []Book
+	for book, err := range gsql.Iterate[Book](ctx, conn, "SELECT * FROM book ORDER BY id").All() {
+		require.NoError(t, err)
+		got = append(got, book)
+	}
+	require.Equal(t, records, got)
+}
+
+func TestRange(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	var got []Book
+	for book, err := range gsql.Range[Book](ctx, conn, "SELECT * FROM book ORDER BY id") {
+		require.NoError(t, err)
+		got = append(got, book)
+	}
+	require.Equal(t, records, got)
+}
+
+func TestIteratorAllStopsEarly(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	var got []Book
+	for book, err := range gsql.Iterate[Book](ctx, conn, "SELECT * FROM book ORDER BY id").All() {
+		require.NoError(t, err)
+		got = append(got, book)
+		if len(got) == 1 {
+			break
+		}
+	}
+	require.Equal(t, records[:1], got)
+}
+
+func TestIteratorAllInitialQueryError(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	var got []Book
+	var gotErr error
+	for book, err := range gsql.Iterate[Book](ctx, conn, "SELECT * FROM no_such_table").All() {
+		gotErr = err
+		got = append(got, book)
+	}
+	require.Error(t, gotErr)
+	require.Len(t, got, 1)
+}
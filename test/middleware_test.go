@@ -0,0 +1,73 @@
+package test_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/reddec/gsql"
+	"github.com/reddec/gsql/otelgsql"
+	"github.com/reddec/gsql/promgsql"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	sdktracetest "go.opentelemetry.io/otel/sdk/trace/tracetest"
+	_ "modernc.org/sqlite"
+)
+
+func TestWrapDBMiddlewares(t *testing.T) {
+	ctx := context.Background()
+	raw, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer raw.Close()
+
+	_, err = raw.ExecContext(ctx, initSQL)
+	require.NoError(t, err)
+
+	recorder := sdktracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("gsql-test")
+
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "gsql_test_query_duration_seconds",
+	}, []string{"query"})
+
+	var logged int
+	logger := slog.New(slog.NewTextHandler(countingWriter{&logged}, nil))
+
+	db := gsql.WrapDB(raw,
+		otelgsql.Middleware(tracer),
+		promgsql.Middleware(histogram),
+		gsql.SlowQueryLogger(0, logger),
+	)
+
+	const query = "SELECT * FROM book WHERE id = ?"
+	book, err := gsql.Get[Book](ctx, db, query, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Demo", book.Title)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, query, spans[0].Name())
+	require.Equal(t, 1, testutil.CollectAndCount(histogram))
+	require.Equal(t, 1, logged)
+
+	// db must work as the sqlx.ExtContext that Insert requires, with no type assertion needed.
+	inserted, err := gsql.Insert[NewBook](ctx, db, "book", []NewBook{
+		{Title: "Wrapped", Author: "X", Year: 2024, Metadata: gsql.JSON[Metadata]{Data: Metadata{Zip: 1}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, inserted, 1)
+}
+
+// countingWriter counts how many times logs are written to it, without asserting on content -
+// the exact slog output format isn't this test's concern.
+type countingWriter struct {
+	n *int
+}
+
+func (w countingWriter) Write(p []byte) (int, error) {
+	*w.n++
+	return len(p), nil
+}
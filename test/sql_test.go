@@ -2,7 +2,12 @@ package test_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/reddec/gsql"
@@ -124,6 +129,140 @@ func TestCacheGet(t *testing.T) {
 	assert.Equal(t, recordReddec, book)
 }
 
+func TestCacheTTL(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	cache := gsql.NewCacheWithOptions[int](func(ctx context.Context) (int, error) {
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}, gsql.WithTTL(20*time.Millisecond))
+
+	v, err := cache.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	v, err = cache.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "still within TTL")
+
+	time.Sleep(30 * time.Millisecond)
+
+	v, err = cache.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "TTL expired, factory should be called again")
+}
+
+func TestCacheNegativeTTL(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	failure := errors.New("boom")
+	cache := gsql.NewCacheWithOptions[int](func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, failure
+	}, gsql.WithNegativeTTL(20*time.Millisecond))
+
+	_, err := cache.Get(ctx)
+	require.ErrorIs(t, err, failure)
+
+	_, err = cache.Get(ctx)
+	require.ErrorIs(t, err, failure)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls), "failure should be cached")
+
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = cache.Get(ctx)
+	require.ErrorIs(t, err, failure)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls), "negative TTL expired, factory should retry")
+}
+
+func TestCacheTTLRetriesAfterTransientFailure(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	failure := errors.New("transient")
+	cache := gsql.NewCacheWithOptions[int](func(ctx context.Context) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 2 {
+			return 0, failure
+		}
+		return int(n), nil
+	}, gsql.WithTTL(10*time.Millisecond))
+
+	v, err := cache.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = cache.Get(ctx)
+	require.ErrorIs(t, err, failure, "stale value should be served alongside the fresh error")
+
+	// Without a negative TTL, a failed refetch must not wedge the cache forever - every
+	// subsequent Get should keep retrying factory, not silently serve the first error forever.
+	time.Sleep(20 * time.Millisecond)
+
+	v, err = cache.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, v, "factory should be retried, not cached as permanently failed")
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	ctx := context.Background()
+
+	var calls int32
+	release := make(chan struct{})
+	cache := gsql.NewCache[int](func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	})
+
+	const waiters = 10
+	var wg sync.WaitGroup
+	results := make([]int, waiters)
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := cache.Get(ctx)
+			require.NoError(t, err)
+			results[i] = v
+		}(i)
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls), "concurrent Get calls should collapse into one factory invocation")
+	for _, v := range results {
+		assert.Equal(t, 42, v)
+	}
+}
+
+func TestCachePeek(t *testing.T) {
+	ctx := context.Background()
+
+	cache := gsql.NewCache[int](func(ctx context.Context) (int, error) {
+		return 7, nil
+	})
+
+	_, ok := cache.Peek()
+	assert.False(t, ok, "nothing fetched yet")
+
+	_, err := cache.Get(ctx)
+	require.NoError(t, err)
+
+	v, ok := cache.Peek()
+	require.True(t, ok)
+	assert.Equal(t, 7, v)
+
+	cache.Invalidate()
+
+	_, ok = cache.Peek()
+	assert.False(t, ok, "invalidated entries aren't peekable")
+}
+
 func TestInsertJSON(t *testing.T) {
 	ctx := context.Background()
 
@@ -155,3 +294,318 @@ func TestInsertJSON(t *testing.T) {
 
 	assert.Equal(t, book, saved)
 }
+
+// NewBook is a row type for [gsql.Insert]: it omits the autoincrement id so a bulk
+// insert can't collide on a zero value shared by every row in the chunk.
+type NewBook struct {
+	Title    string
+	Author   string
+	Year     int
+	Metadata gsql.JSON[Metadata]
+}
+
+func TestInsert(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	rows := []NewBook{
+		{Title: "Dune", Author: "Frank Herbert", Year: 1965, Metadata: gsql.AsJSON(Metadata{Zip: 1})},
+		{Title: "Neuromancer", Author: "William Gibson", Year: 1984, Metadata: gsql.AsJSON(Metadata{Zip: 2})},
+	}
+
+	echoed, err := gsql.Insert[NewBook](ctx, conn, "book", rows)
+	require.NoError(t, err)
+	assert.Equal(t, rows, echoed)
+
+	list, err := gsql.List[Book](ctx, conn, "SELECT * FROM book WHERE title = 'Dune' OR title = 'Neuromancer' ORDER BY title")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "Dune", list[0].Title)
+	assert.Equal(t, "Neuromancer", list[1].Title)
+}
+
+func TestInsertUpsertWithReturning(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	updated, err := gsql.Insert[Book](ctx, conn, "book", []Book{{ID: recordReddec.ID, Title: "Demo", Author: "reddec", Year: 2024, Metadata: recordReddec.Metadata}},
+		gsql.WithConflict("id", "DO UPDATE SET year = excluded.year"),
+		gsql.WithReturning("id", "title", "author", "year", "metadata"),
+	)
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Equal(t, 2024, updated[0].Year)
+}
+
+func TestInsertChunksAcrossPlaceholderLimit(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	// NewBook has 4 columns, so sqlite's 999-placeholder limit chunks at 249 rows per
+	// statement; 300 rows forces Insert's chunk loop to run twice.
+	const n = 300
+	rows := make([]NewBook, n)
+	for i := range rows {
+		rows[i] = NewBook{Title: fmt.Sprintf("Bulk %d", i), Author: "Bulk", Year: 2000 + i, Metadata: gsql.AsJSON(Metadata{Zip: i})}
+	}
+
+	echoed, err := gsql.Insert[NewBook](ctx, conn, "book", rows)
+	require.NoError(t, err)
+	assert.Equal(t, rows, echoed)
+
+	var count int
+	require.NoError(t, conn.GetContext(ctx, &count, "SELECT COUNT(*) FROM book WHERE author = 'Bulk'"))
+	assert.Equal(t, n, count)
+}
+
+func TestInsertReturningChunksAcrossPlaceholderLimit(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	// Book has 5 columns (id included), so sqlite's 999-placeholder limit chunks at 199 rows
+	// per statement; 300 rows forces the RETURNING path across multiple chunks too. Explicit,
+	// distinct IDs avoid the zero-value autoincrement collision documented on [gsql.Insert].
+	const n = 300
+	rows := make([]Book, n)
+	for i := range rows {
+		rows[i] = Book{ID: int64(1000 + i), Title: fmt.Sprintf("Returned %d", i), Author: "Bulk", Year: 2000 + i, Metadata: gsql.AsJSON(Metadata{Zip: i})}
+	}
+
+	returned, err := gsql.Insert[Book](ctx, conn, "book", rows, gsql.WithReturning("id", "title", "author", "year", "metadata"))
+	require.NoError(t, err)
+	require.Len(t, returned, n)
+
+	var count int
+	require.NoError(t, conn.GetContext(ctx, &count, "SELECT COUNT(*) FROM book WHERE author = 'Bulk'"))
+	assert.Equal(t, n, count)
+}
+
+// NewBookNullableAuthor is a row type with a nullable column mapped to a pointer field, to
+// exercise [gsql.Insert] reading a nil field from a row it never needs to mutate.
+type NewBookNullableAuthor struct {
+	Title    string
+	Author   *string
+	Year     int
+	Metadata gsql.JSON[Metadata]
+}
+
+func TestInsertNilPointerField(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	rows := []NewBookNullableAuthor{
+		{Title: "Anonymous", Author: nil, Year: 2020, Metadata: gsql.AsJSON(Metadata{Zip: 0})},
+	}
+
+	echoed, err := gsql.Insert[NewBookNullableAuthor](ctx, conn, "book", rows)
+	require.NoError(t, err)
+	assert.Equal(t, rows, echoed)
+
+	var authorIsNull bool
+	require.NoError(t, conn.GetContext(ctx, &authorIsNull, "SELECT author IS NULL FROM book WHERE title = 'Anonymous'"))
+	assert.True(t, authorIsNull)
+}
+
+// Info is embedded anonymously by EmbeddedNewBook so its fields are promoted to top-level
+// columns, the same way [sqlx] scans them for [gsql.Get] and [gsql.List].
+type Info struct {
+	Author string
+	Year   int
+}
+
+type EmbeddedNewBook struct {
+	Title string
+	Info
+	Metadata gsql.JSON[Metadata]
+}
+
+func TestInsertEmbeddedFields(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	rows := []EmbeddedNewBook{
+		{Title: "Hyperion", Info: Info{Author: "Dan Simmons", Year: 1989}, Metadata: gsql.AsJSON(Metadata{Zip: 42})},
+	}
+
+	echoed, err := gsql.Insert[EmbeddedNewBook](ctx, conn, "book", rows)
+	require.NoError(t, err)
+	assert.Equal(t, rows, echoed)
+
+	saved, err := gsql.Get[Book](ctx, conn, "SELECT * FROM book WHERE title = 'Hyperion'")
+	require.NoError(t, err)
+	assert.Equal(t, "Dan Simmons", saved.Author)
+	assert.Equal(t, 1989, saved.Year)
+}
+
+func TestInsertNoColumnsErrors(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	type noColumns struct {
+		unexported string
+	}
+
+	_, err = gsql.Insert[noColumns](ctx, conn, "book", []noColumns{{unexported: "x"}})
+	require.Error(t, err)
+}
+
+func TestInTxCommit(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	err = gsql.InTx(ctx, conn, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("UPDATE book SET year = 2023 WHERE id = 1")
+		return err
+	})
+	require.NoError(t, err)
+
+	book, err := gsql.Get[Book](ctx, conn, "SELECT * FROM book WHERE id = 1")
+	require.NoError(t, err)
+	assert.Equal(t, 2023, book.Year)
+}
+
+func TestInTxRollbackOnError(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	boom := errors.New("boom")
+	err = gsql.InTx(ctx, conn, nil, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec("UPDATE book SET year = 2023 WHERE id = 1"); err != nil {
+			return err
+		}
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+
+	book, err := gsql.Get[Book](ctx, conn, "SELECT * FROM book WHERE id = 1")
+	require.NoError(t, err)
+	assert.Equal(t, recordReddec.Year, book.Year, "failed transaction must be rolled back")
+}
+
+func TestInTxRetryOnSerializationFailure(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	var attempts int
+	err = gsql.InTxRetry(ctx, conn, nil, gsql.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}, func(tx *sqlx.Tx, txCtx *gsql.TxCtx) error {
+		attempts++
+		txCtx.Set("attempt", attempts)
+		if attempts < 2 {
+			return errors.New("could not serialize access due to concurrent update (SQLSTATE 40001)")
+		}
+		_, err := tx.Exec("UPDATE book SET year = 2023 WHERE id = 1")
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	book, err := gsql.Get[Book](ctx, conn, "SELECT * FROM book WHERE id = 1")
+	require.NoError(t, err)
+	assert.Equal(t, 2023, book.Year)
+}
+
+func TestStatementGetTx(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	var byID gsql.Statement[Book] = "SELECT * FROM book WHERE id = ?"
+
+	err = gsql.InTx(ctx, conn, nil, func(tx *sqlx.Tx) error {
+		book, err := byID.GetTx(ctx, tx, recordReddec.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, recordReddec, book)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestNamedStatementListTx(t *testing.T) {
+	ctx := context.Background()
+
+	conn, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(initSQL)
+	require.NoError(t, err)
+
+	var byYear gsql.NamedStatement[Book, map[string]any] = "SELECT * FROM book WHERE year >= :min ORDER BY year"
+
+	err = gsql.InTx(ctx, conn, nil, func(tx *sqlx.Tx) error {
+		list, err := byYear.ListTx(ctx, tx, map[string]any{"min": 1980})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, []Book{recordPike, recordReddec}, list)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,156 @@
+package gsql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// InTx begins a transaction on db, calls fn, commits on nil error and rolls back otherwise. A
+// panic inside fn rolls back the transaction and is re-raised after rollback.
+func InTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RetryPolicy controls how [InTxRetry] retries a transaction after a retryable error. Zero
+// values fall back to sensible defaults (3 attempts, 50ms base delay, 2s max delay).
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts, including the first one.
+	BaseDelay   time.Duration // base of the exponential backoff.
+	MaxDelay    time.Duration // upper bound of the backoff, before jitter.
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 50 * time.Millisecond
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 2 * time.Second
+	}
+	return p
+}
+
+// backoff returns the delay before the given retry attempt (1-based: the first retry, after the
+// initial attempt), as exponential backoff with full jitter, capped at MaxDelay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// TxCtx carries per-attempt state across retries of [InTxRetry]. Use it to stash values
+// captured inside fn (e.g. rows about to be published) and read them back once InTxRetry
+// returns; it is reset before every retry so state from a rolled-back attempt can't leak into
+// the next one.
+type TxCtx struct {
+	mu     sync.Mutex
+	values map[any]any
+}
+
+// Set stores value under key for the current attempt.
+func (tc *TxCtx) Set(key, value any) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.values == nil {
+		tc.values = make(map[any]any)
+	}
+	tc.values[key] = value
+}
+
+// Value returns the value stored under key for the current attempt, or nil.
+func (tc *TxCtx) Value(key any) any {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.values[key]
+}
+
+func (tc *TxCtx) reset() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.values = nil
+}
+
+// InTxRetry is like [InTx] but runs fn up to policy.MaxAttempts times in total, with exponential
+// backoff and jitter, when it fails with a serialization failure (Postgres SQLSTATE 40001),
+// deadlock (Postgres 40P01), or a busy SQLite database. fn receives a [TxCtx] handle that is
+// reset before each retry, so state captured on a rolled-back attempt doesn't leak into the next one.
+func InTxRetry(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions, policy RetryPolicy, fn func(tx *sqlx.Tx, txCtx *TxCtx) error) error {
+	policy = policy.withDefaults()
+
+	var txCtx TxCtx
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			txCtx.reset()
+			select {
+			case <-time.After(policy.backoff(attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err = InTx(ctx, db, opts, func(tx *sqlx.Tx) error {
+			return fn(tx, &txCtx)
+		})
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// isRetryable reports whether err looks like a transient serialization/deadlock/busy failure
+// worth retrying. Driver errors are matched by message rather than by type to avoid a hard
+// dependency on any particular SQL driver package.
+func isRetryable(err error) bool {
+	msg := err.Error()
+	for _, marker := range []string{"40001", "40P01", "SQLITE_BUSY", "database is locked"} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTx is a wrapper around [Statement.Get] for use inside a transaction started by [InTx] or [InTxRetry].
+func (st Statement[T]) GetTx(ctx context.Context, tx *sqlx.Tx, args ...any) (T, error) {
+	return st.Get(ctx, tx, args...)
+}
+
+// ListTx is a wrapper around [NamedStatement.List] for use inside a transaction started by [InTx] or [InTxRetry].
+func (st NamedStatement[T, Params]) ListTx(ctx context.Context, tx *sqlx.Tx, arg Params) ([]T, error) {
+	return st.List(ctx, tx, arg)
+}